@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestSyftRunsInDetectedContainerRuntime exercises the runtime-detection
+// abstraction end-to-end against whatever container engine containerRuntime
+// resolves, so it doesn't silently rot. Set SYFT_TEST_CONTAINER_RUNTIME=docker
+// or =podman to pin which engine this runs against; otherwise it's whichever
+// one is found on PATH.
+func TestSyftRunsInDetectedContainerRuntime(t *testing.T) {
+	image := "alpine:3.19"
+
+	switch containerRuntime(t) {
+	case "podman":
+		pullPodmanImage(t, image)
+	default:
+		pullDockerImage(t, image)
+	}
+
+	cmd, stdout, stderr := runSyftInDetectedContainerRuntime(t, nil, image, "packages", "-o", "json")
+	if cmd.ProcessState.ExitCode() != 0 {
+		t.Log("STDOUT", stdout)
+		t.Log("STDERR", stderr)
+		t.Fatalf("syft failed to run inside %s container", containerRuntime(t))
+	}
+}
+
+// TestPodmanContainerRuntime exercises the podman path chunk0-1 added for
+// both running syft and validating its output, so runSyftInPodman and
+// runCycloneDXInPodman don't go uncalled. It runs when containerRuntime
+// resolves podman (SYFT_TEST_CONTAINER_RUNTIME=podman, or podman is the only
+// engine on PATH); otherwise it's skipped, since most hosts default to docker.
+func TestPodmanContainerRuntime(t *testing.T) {
+	if containerRuntime(t) != "podman" {
+		t.Skip("set SYFT_TEST_CONTAINER_RUNTIME=podman to exercise the podman path")
+	}
+
+	image := "alpine:3.19"
+	pullPodmanImage(t, image)
+
+	if cmd, stdout, stderr := runSyftInPodman(t, nil, image, "packages", "-o", "json"); cmd.ProcessState.ExitCode() != 0 {
+		t.Log("STDOUT", stdout)
+		t.Log("STDERR", stderr)
+		t.Fatalf("syft failed to run inside podman container")
+	}
+
+	sbomPath := filepath.Join(t.TempDir(), "sbom.cdx.json")
+	_, stdout, stderr := runSyft(t, nil, "dir:"+repoRoot(t), "-o", "cyclonedx-json="+sbomPath)
+	if _, err := os.Stat(sbomPath); err != nil {
+		t.Fatalf("expected cyclonedx-json SBOM to be written: %+v\nSTDOUT:\n%s\nSTDERR:\n%s", err, stdout, stderr)
+	}
+
+	f, err := os.Open(sbomPath)
+	if err != nil {
+		t.Fatalf("could not open generated SBOM: %+v", err)
+	}
+	defer f.Close()
+
+	cmd, stdout, stderr := runCycloneDXInPodman(t, nil, "cyclonedx/cyclonedx-cli:latest", f,
+		"validate", "--input-format", "json", "--input-version", "v1_4", "--input-file", "/sbom",
+	)
+	if cmd.ProcessState.ExitCode() != 0 {
+		t.Log("STDOUT", stdout)
+		t.Log("STDERR", stderr)
+		t.Fatalf("SBOM %s failed CycloneDX validation", sbomPath)
+	}
+}
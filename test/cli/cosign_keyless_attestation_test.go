@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testRegistry returns the registry the keyless attestation test pushes to
+// and attests against. It defaults to a local registry (e.g. one started
+// with `docker run -d -p 5000:5000 registry:2`) so the test never depends on
+// push access to a public registry like docker.io.
+func testRegistry() string {
+	if r := os.Getenv("SYFT_TEST_REGISTRY"); r != "" {
+		return r
+	}
+	return "localhost:5000"
+}
+
+// pushEphemeralImage tags sourceImage under repo in testRegistry and pushes
+// it, returning a digest reference (repo@sha256:...) that cosign attest and
+// verify-attestation can target without racing a mutable tag.
+func pushEphemeralImage(t testing.TB, sourceImage, repo string) string {
+	localImage := testRegistry() + "/" + repo + ":latest"
+
+	cmd := exec.Command("docker", "tag", sourceImage, localImage)
+	stdout, stderr, _ := runCommand(cmd, nil)
+	if cmd.ProcessState.ExitCode() != 0 {
+		t.Log("STDOUT", stdout)
+		t.Log("STDERR", stderr)
+		t.Fatalf("could not tag %s as %s", sourceImage, localImage)
+	}
+
+	cmd = exec.Command("docker", "push", localImage)
+	stdout, stderr, _ = runCommand(cmd, nil)
+	if cmd.ProcessState.ExitCode() != 0 {
+		t.Log("STDOUT", stdout)
+		t.Log("STDERR", stderr)
+		t.Fatalf("could not push %s", localImage)
+	}
+
+	cmd = exec.Command("docker", "inspect", "--format={{range .RepoDigests}}{{println .}}{{end}}", localImage)
+	stdout, stderr, _ = runCommand(cmd, nil)
+	// the image also carries the source image's digest (e.g. from docker.io),
+	// so pick the one that belongs to the pushed repo
+	digestRef := ""
+	for _, ref := range strings.Split(stdout, "\n") {
+		ref = strings.TrimSpace(ref)
+		if strings.Contains(ref, "/"+repo+"@") {
+			digestRef = ref
+			break
+		}
+	}
+	if cmd.ProcessState.ExitCode() != 0 || digestRef == "" {
+		t.Log("STDOUT", stdout)
+		t.Log("STDERR", stderr)
+		t.Fatalf("could not resolve digest for %s", localImage)
+	}
+
+	return digestRef
+}
+
+// dsseEnvelope is the subset of cosign's verify-attestation JSON output we
+// need: the DSSE envelope wrapping a base64-encoded in-toto statement.
+type dsseEnvelope struct {
+	PayloadType string `json:"payloadType"`
+	Payload     string `json:"payload"`
+}
+
+// inTotoStatement is the subset of an in-toto statement we need to confirm
+// the attested predicate round-tripped.
+type inTotoStatement struct {
+	PredicateType string `json:"predicateType"`
+}
+
+// assertAttestationPredicateType fails the test unless stdout from `cosign
+// verify-attestation` contains a DSSE envelope whose base64-encoded payload
+// decodes to an in-toto statement with the given predicateType. The literal
+// predicate type URL never appears as plaintext in stdout since it's nested
+// inside the base64 payload, so it must be decoded before comparing.
+func assertAttestationPredicateType(t testing.TB, stdout, predicateType string) {
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		var envelope dsseEnvelope
+		if err := json.Unmarshal([]byte(line), &envelope); err != nil {
+			continue
+		}
+
+		payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+		if err != nil {
+			continue
+		}
+
+		var statement inTotoStatement
+		if err := json.Unmarshal(payload, &statement); err != nil {
+			continue
+		}
+
+		if statement.PredicateType == predicateType {
+			return
+		}
+	}
+
+	t.Errorf("expected verified attestation to round-trip the %s predicate, got:\n%s", predicateType, stdout)
+}
+
+// TestCosignKeylessAttestationRoundTrip signs syft-generated CycloneDX and
+// SPDX SBOMs as keyless in-toto attestations and verifies them, giving us
+// regression coverage for the attest->verify flow under the modern
+// OIDC/Fulcio workflow alongside the legacy key-based coverage that setupPKI
+// exercises. The target image is pushed to a registry the test controls
+// (testRegistry) since cosign attest uploads the attestation to the image's
+// registry, which we can't do against a public image like docker.io/alpine.
+func TestCosignKeylessAttestationRoundTrip(t *testing.T) {
+	teardown := setupKeylessPKI(t)
+	defer teardown()
+
+	certIdentity := os.Getenv("SYFT_TEST_COSIGN_CERT_IDENTITY")
+	certOIDCIssuer := os.Getenv("SYFT_TEST_COSIGN_CERT_OIDC_ISSUER")
+	if certIdentity == "" || certOIDCIssuer == "" {
+		t.Skip("SYFT_TEST_COSIGN_CERT_IDENTITY/SYFT_TEST_COSIGN_CERT_OIDC_ISSUER not set, skipping keyless verification")
+	}
+
+	sourceImage := "alpine:3.19"
+	pullDockerImage(t, sourceImage)
+	image := pushEphemeralImage(t, sourceImage, "syft-test/cosign-keyless")
+
+	tests := []struct {
+		name          string
+		outputFormat  string
+		fileExt       string
+		predicateType string
+	}{
+		{
+			name:          "cyclonedx-json",
+			outputFormat:  "cyclonedx-json",
+			fileExt:       "cdx.json",
+			predicateType: "https://cyclonedx.org/bom",
+		},
+		{
+			name:          "spdx-json",
+			outputFormat:  "spdx-json",
+			fileExt:       "spdx.json",
+			predicateType: "https://spdx.dev/Document",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sbomPath := filepath.Join(t.TempDir(), "sbom."+test.fileExt)
+			_, stdout, stderr := runSyft(t, nil, "dir:"+repoRoot(t), "-o", test.outputFormat+"="+sbomPath)
+			if _, err := os.Stat(sbomPath); err != nil {
+				t.Fatalf("expected %s SBOM to be written: %+v\nSTDOUT:\n%s\nSTDERR:\n%s", test.outputFormat, err, stdout, stderr)
+			}
+
+			sbomFile, err := os.Open(sbomPath)
+			if err != nil {
+				t.Fatalf("could not open generated SBOM: %+v", err)
+			}
+			defer sbomFile.Close()
+
+			predicatePath := attestationPredicatePath(t, sbomFile)
+
+			if cmd, stdout, stderr := runCosignAttestKeyless(t, nil, image, predicatePath, test.predicateType); cmd.ProcessState.ExitCode() != 0 {
+				t.Log("STDOUT", stdout)
+				t.Log("STDERR", stderr)
+				t.Fatalf("could not attest %s in keyless mode", image)
+			}
+
+			cmd, stdout, stderr := runCosignVerifyAttestationKeyless(t, nil, image, certIdentity, certOIDCIssuer)
+			if cmd.ProcessState.ExitCode() != 0 {
+				t.Log("STDOUT", stdout)
+				t.Log("STDERR", stderr)
+				t.Fatalf("keyless attestation for %s did not verify", image)
+			}
+
+			assertAttestationPredicateType(t, stdout, test.predicateType)
+		})
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package cli
+
+import (
+	"os"
+	"syscall"
+)
+
+// abortProcess sends SIGABRT to a timed-out command so it prints a stack
+// trace before dying.
+func abortProcess(p *os.Process) error {
+	return p.Signal(syscall.SIGABRT)
+}
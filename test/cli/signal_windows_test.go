@@ -0,0 +1,12 @@
+//go:build windows
+
+package cli
+
+import "os"
+
+// abortProcess kills a timed-out command outright. Windows can't deliver
+// SIGABRT (and os.Process.Signal only supports os.Kill there), so unlike the
+// other platforms this won't leave a stack trace behind.
+func abortProcess(p *os.Process) error {
+	return p.Kill()
+}
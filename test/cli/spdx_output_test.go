@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestSPDXOutputIsValid generates each SPDX output format syft supports and
+// validates it against the SPDX spec, mirroring the schema-conformance
+// guarantee the CycloneDX output tests already get from runCycloneDXInDocker.
+func TestSPDXOutputIsValid(t *testing.T) {
+	tests := []struct {
+		name         string
+		outputFormat string
+		fileExt      string
+		validateAs   string
+	}{
+		{
+			name:         "spdx-json",
+			outputFormat: "spdx-json",
+			fileExt:      "spdx.json",
+			validateAs:   "json",
+		},
+		{
+			name:         "spdx-tag-value",
+			outputFormat: "spdx-tag-value",
+			fileExt:      "spdx",
+			validateAs:   "tag-value",
+		},
+		{
+			name:         "spdx-2.3",
+			outputFormat: "spdx-json@2.3",
+			fileExt:      "spdx.json",
+			validateAs:   "json",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sbomPath := filepath.Join(t.TempDir(), "sbom."+test.fileExt)
+			_, stdout, stderr := runSyft(t, nil, "dir:"+repoRoot(t), "-o", test.outputFormat+"="+sbomPath)
+			if _, err := os.Stat(sbomPath); err != nil {
+				t.Fatalf("expected %s SBOM to be written: %+v\nSTDOUT:\n%s\nSTDERR:\n%s", test.outputFormat, err, stdout, stderr)
+			}
+
+			assertValidSPDX(t, sbomPath, test.validateAs)
+		})
+	}
+}
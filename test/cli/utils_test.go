@@ -11,7 +11,6 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
-	"syscall"
 	"testing"
 	"text/template"
 	"time"
@@ -64,54 +63,259 @@ func setupPKI(t *testing.T, pw string) func() {
 	}
 }
 
+// setupKeylessPKI configures the environment for cosign's keyless
+// (OIDC/Fulcio) signing and verification flow, trading the password-protected
+// keypair that setupPKI generates for an ambient OIDC token. The caller is
+// responsible for providing SIGSTORE_ID_TOKEN; set SYFT_TEST_SIGSTORE_STAGING=1
+// to point cosign at the sigstore staging Fulcio/Rekor instances instead of
+// the production ones.
+func setupKeylessPKI(t *testing.T) func() {
+	if os.Getenv("SIGSTORE_ID_TOKEN") == "" {
+		t.Skip("SIGSTORE_ID_TOKEN not set, skipping keyless cosign flow")
+	}
+
+	env := map[string]string{
+		"COSIGN_EXPERIMENTAL": "1",
+	}
+	if os.Getenv("SYFT_TEST_SIGSTORE_STAGING") == "1" {
+		env["COSIGN_FULCIO_URL"] = "https://fulcio.sigstage.dev"
+		env["COSIGN_REKOR_URL"] = "https://rekor.sigstage.dev"
+		env["COSIGN_MIRROR"] = "https://tuf-repo-cdn.sigstage.dev"
+		env["COSIGN_ROOT"] = "https://tuf-repo-cdn.sigstage.dev/root.json"
+	}
+
+	for k, v := range env {
+		if err := os.Setenv(k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return func() {
+		for k := range env {
+			if err := os.Unsetenv(k); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+// attestationPredicatePath copies a syft-generated SBOM file to a path cosign
+// can consume as the raw predicate body for `cosign attest --predicate
+// <path> --type <type>`. cosign builds the in-toto statement (and its own
+// predicateType field) itself, so the predicate file must be the SBOM bytes
+// as-is, not a pre-wrapped envelope.
+func attestationPredicatePath(t *testing.T, sbomFile *os.File) string {
+	sbom, err := os.ReadFile(sbomFile.Name())
+	if err != nil {
+		t.Fatalf("could not read sbom for attestation predicate: %+v", err)
+	}
+
+	predicatePath := sbomFile.Name() + ".predicate.json"
+	if err := os.WriteFile(predicatePath, sbom, 0644); err != nil {
+		t.Fatalf("could not write attestation predicate: %+v", err)
+	}
+
+	return predicatePath
+}
+
+// runCosignAttestKeyless signs the given predicate against image using
+// cosign's keyless flow, authenticating with the ambient SIGSTORE_ID_TOKEN
+// rather than a local private key.
+func runCosignAttestKeyless(t testing.TB, env map[string]string, image, predicatePath, predicateType string) (*exec.Cmd, string, string) {
+	return runCosign(t, env,
+		"attest",
+		"--yes",
+		"--predicate", predicatePath,
+		"--type", predicateType,
+		"--identity-token", os.Getenv("SIGSTORE_ID_TOKEN"),
+		image,
+	)
+}
+
+// runCosignVerifyAttestationKeyless verifies a keyless attestation against
+// image, including its transparency-log inclusion proof, pinning the
+// expected certificate identity and OIDC issuer so an attacker-controlled
+// identity can't pass verification.
+func runCosignVerifyAttestationKeyless(t testing.TB, env map[string]string, image, certIdentity, certOIDCIssuer string) (*exec.Cmd, string, string) {
+	return runCosign(t, env,
+		"verify-attestation",
+		"--certificate-identity", certIdentity,
+		"--certificate-oidc-issuer", certOIDCIssuer,
+		image,
+	)
+}
+
 func getFixtureImage(t testing.TB, fixtureImageName string) string {
 	t.Logf("obtaining fixture image for %s", fixtureImageName)
 	imagetest.GetFixtureImage(t, "docker-archive", fixtureImageName)
 	return imagetest.GetFixtureImageTarPath(t, fixtureImageName)
 }
 
-func pullDockerImage(t testing.TB, image string) {
-	cmd := exec.Command("docker", "pull", image)
+// containerRuntimeEnvVar selects which container engine the CLI integration
+// suite shells out to. When unset, the runtime is detected by probing $PATH,
+// preferring docker (the common case) and falling back to podman (e.g.
+// rootless CI environments and developer machines without Docker Desktop).
+const containerRuntimeEnvVar = "SYFT_TEST_CONTAINER_RUNTIME"
+
+func containerRuntime(t testing.TB) string {
+	if rt := os.Getenv(containerRuntimeEnvVar); rt != "" {
+		return rt
+	}
+	if _, err := exec.LookPath("docker"); err == nil {
+		return "docker"
+	}
+	if _, err := exec.LookPath("podman"); err == nil {
+		return "podman"
+	}
+	t.Fatalf("unable to find a container runtime on PATH (tried docker, podman)")
+	return ""
+}
+
+func isPodman(runtime string) bool {
+	return runtime == "podman"
+}
+
+// containerTTYFlag returns the flag used to allocate a pseudo-TTY; docker and
+// podman both accept the short form.
+func containerTTYFlag() string {
+	return "-t"
+}
+
+// containerVolumeArg returns a bind-mount argument for the given host path
+// and container path, adding the SELinux relabeling suffix podman requires
+// to read the mount under an enforcing policy.
+func containerVolumeArg(runtime, hostPath, containerPath string) string {
+	arg := fmt.Sprintf("%s:%s", hostPath, containerPath)
+	if isPodman(runtime) {
+		arg += ":Z"
+	}
+	return arg
+}
+
+func pullImage(t testing.TB, runtime, image string) {
+	cmd := exec.Command(runtime, "pull", image)
 	stdout, stderr, _ := runCommand(cmd, nil)
 	if cmd.ProcessState.ExitCode() != 0 {
 		t.Log("STDOUT", stdout)
 		t.Log("STDERR", stderr)
-		t.Fatalf("could not pull docker image")
+		t.Fatalf("could not pull %s image", runtime)
 	}
 }
 
+func pullDockerImage(t testing.TB, image string) {
+	pullImage(t, "docker", image)
+}
+
+func pullPodmanImage(t testing.TB, image string) {
+	pullImage(t, "podman", image)
+}
+
 // docker run -v $(pwd)/sbom:/sbom cyclonedx/cyclonedx-cli:latest validate --input-format json --input-version v1_4 --input-file /sbom
 func runCycloneDXInDocker(t testing.TB, env map[string]string, image string, f *os.File, args ...string) (*exec.Cmd, string, string) {
+	return runCycloneDXInContainer(t, "docker", env, image, f, args...)
+}
+
+// runCycloneDXInPodman is the podman equivalent of runCycloneDXInDocker.
+func runCycloneDXInPodman(t testing.TB, env map[string]string, image string, f *os.File, args ...string) (*exec.Cmd, string, string) {
+	return runCycloneDXInContainer(t, "podman", env, image, f, args...)
+}
+
+func runCycloneDXInContainer(t testing.TB, runtime string, env map[string]string, image string, f *os.File, args ...string) (*exec.Cmd, string, string) {
+	allArgs := append(
+		[]string{
+			"run",
+			containerTTYFlag(),
+			"-v",
+			containerVolumeArg(runtime, f.Name(), "/sbom"),
+			image,
+		},
+		args...,
+	)
+	cmd := exec.Command(runtime, allArgs...)
+	stdout, stderr, _ := runCommand(cmd, env)
+	return cmd, stdout, stderr
+}
+
+// runSPDXValidatorInContainer shells out to a containerized SPDX validator
+// using whichever engine containerRuntime resolves, so SPDX validation works
+// on rootless podman-only hosts the same way runCycloneDXInPodman already
+// does for CycloneDX.
+func runSPDXValidatorInContainer(t testing.TB, env map[string]string, image string, f *os.File, containerPath string, args ...string) (*exec.Cmd, string, string) {
+	runtime := containerRuntime(t)
 	allArgs := append(
 		[]string{
 			"run",
-			"-t",
+			containerTTYFlag(),
 			"-v",
-			fmt.Sprintf("%s:/sbom", f.Name()),
+			containerVolumeArg(runtime, f.Name(), containerPath),
 			image,
 		},
 		args...,
 	)
-	cmd := exec.Command("docker", allArgs...)
+	cmd := exec.Command(runtime, allArgs...)
 	stdout, stderr, _ := runCommand(cmd, env)
 	return cmd, stdout, stderr
 }
 
+// assertValidSPDX fails the test if the SBOM at file does not conform to the
+// SPDX spec, mirroring the schema-conformance guarantee runCycloneDXInDocker
+// gives us for CycloneDX output. format selects the container-side file
+// extension ("json" or "tag-value") so pyspdxtools can tell the two SPDX
+// serializations apart; pyspdxtools has no separate flag for that, and it
+// infers the spec version from the document itself.
+func assertValidSPDX(t testing.TB, file string, format string) {
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatalf("could not open SBOM for SPDX validation: %+v", err)
+	}
+	defer f.Close()
+
+	containerPath := "/sbom.spdx.json"
+	if format == "tag-value" {
+		containerPath = "/sbom.spdx"
+	}
+
+	cmd, stdout, stderr := runSPDXValidatorInContainer(t, nil, "pyspdxtools/pyspdxtools:latest", f, containerPath,
+		"pyspdxtools", "-i", containerPath,
+	)
+	if cmd.ProcessState.ExitCode() != 0 {
+		t.Log("STDOUT", stdout)
+		t.Log("STDERR", stderr)
+		t.Errorf("SBOM %s failed SPDX validation", file)
+	}
+}
+
 func runSyftInDocker(t testing.TB, env map[string]string, image string, args ...string) (*exec.Cmd, string, string) {
+	return runSyftInContainer(t, "docker", env, image, args...)
+}
+
+// runSyftInPodman is the podman equivalent of runSyftInDocker.
+func runSyftInPodman(t testing.TB, env map[string]string, image string, args ...string) (*exec.Cmd, string, string) {
+	return runSyftInContainer(t, "podman", env, image, args...)
+}
+
+// runSyftInDetectedContainerRuntime runs syft in whichever container runtime
+// containerRuntime resolves (SYFT_TEST_CONTAINER_RUNTIME, or the first of
+// docker/podman found on PATH), so callers don't need to hard-code one.
+func runSyftInDetectedContainerRuntime(t testing.TB, env map[string]string, image string, args ...string) (*exec.Cmd, string, string) {
+	return runSyftInContainer(t, containerRuntime(t), env, image, args...)
+}
+
+func runSyftInContainer(t testing.TB, runtime string, env map[string]string, image string, args ...string) (*exec.Cmd, string, string) {
 	allArgs := append(
 		[]string{
 			"run",
-			"-t",
+			containerTTYFlag(),
 			"-e",
 			"SYFT_CHECK_FOR_APP_UPDATE=false",
 			"-v",
-			fmt.Sprintf("%s:/syft", getSyftBinaryLocationByOS(t, "linux")),
+			containerVolumeArg(runtime, getSyftBinaryLocationByOS(t, "linux"), "/syft"),
 			image,
 			"/syft",
 		},
 		args...,
 	)
-	cmd := exec.Command("docker", allArgs...)
+	cmd := exec.Command(runtime, allArgs...)
 	stdout, stderr, _ := runCommand(cmd, env)
 	return cmd, stdout, stderr
 }
@@ -147,7 +351,7 @@ func runSyftCommand(t testing.TB, env map[string]string, expectError bool, args
 
 		if cmd != nil && cmd.Process != nil {
 			// get a stack trace printed
-			err := cmd.Process.Signal(syscall.SIGABRT)
+			err := abortProcess(cmd.Process)
 			if err != nil {
 				t.Errorf("error aborting: %+v", err)
 			}
@@ -202,7 +406,7 @@ func runCommandObj(t testing.TB, cmd *exec.Cmd, env map[string]string, expectErr
 
 		if cmd != nil && cmd.Process != nil {
 			// get a stack trace printed
-			err := cmd.Process.Signal(syscall.SIGABRT)
+			err := abortProcess(cmd.Process)
 			if err != nil {
 				t.Errorf("error aborting: %+v", err)
 			}
@@ -329,9 +533,15 @@ func buildSyftWithGo(dir string, outfile string) (string, string, error) {
 	}
 	r := releaser{}
 	_ = d.Decode(&r)
+
+	buildID := "linux-build"
+	if runtime.GOOS == "windows" {
+		buildID = "windows-build"
+	}
+
 	ldflags := ""
 	for _, b := range r.Builds {
-		if b.ID == "linux-build" {
+		if b.ID == buildID {
 			ldflags = executeTemplate(b.LDFlags, struct {
 				Version string
 				Commit  string
@@ -357,9 +567,10 @@ func buildSyftWithGo(dir string, outfile string) (string, string, error) {
 	)
 
 	cmd.Dir = dir
-	stdout, stderr, err := runCommand(cmd, map[string]string{
+	env := map[string]string{
 		"CGO_ENABLED": "0",
-	})
+	}
+	stdout, stderr, err := runCommand(cmd, env)
 	return stdout, stderr, err
 }
 
@@ -418,6 +629,10 @@ func getSyftBinaryLocationByOS(t testing.TB, goOS string) string {
 	switch goOS {
 	case "darwin", "linux":
 		return path.Join(repoRoot(t), fmt.Sprintf("snapshot/%s-build_%s_%s/syft", goOS, goOS, archPath))
+	case "windows":
+		// goreleaser names the windows build dir the same way as the others, but
+		// the binary itself carries the .exe suffix
+		return filepath.Join(repoRoot(t), fmt.Sprintf("snapshot/windows-build_windows_%s", archPath), "syft.exe")
 	default:
 		t.Fatalf("unsupported OS: %s", runtime.GOOS)
 	}